@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func newTestRouter(cfg Config, role string) *gin.Engine{
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	group := router.Group("/")
+	group.Use(cfg.Middleware())
+	if role != ""{
+		group.Use(RequireRole(role))
+	}
+	group.GET("/ok", func(c *gin.Context){ c.Status(http.StatusOK) })
+	return router
+}
+
+func doRequest(router *gin.Engine, token string) *httptest.ResponseRecorder{
+	req := httptest.NewRequest(http.MethodGet, "/ok", nil)
+	if token != ""{
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMiddlewareRejectsMissingToken(t *testing.T){
+	cfg := Config{Secret: []byte("test-secret")}
+	router := newTestRouter(cfg, "")
+
+	rec := doRequest(router, "")
+	if rec.Code != http.StatusUnauthorized{
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestMiddlewareRejectsExpiredToken(t *testing.T){
+	cfg := Config{Secret: []byte("test-secret")}
+	token, err := cfg.IssueToken("tester", nil, -time.Minute)
+	if err != nil{
+		t.Fatalf("issue token: %v", err)
+	}
+	router := newTestRouter(cfg, "")
+
+	rec := doRequest(router, token)
+	if rec.Code != http.StatusUnauthorized{
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestMiddlewareRejectsUnexpectedSigningMethod(t *testing.T){
+	cfg := Config{Secret: []byte("test-secret")}
+
+	claims := Claims{
+		Roles: []string{"reader"},
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "tester",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodNone, claims)
+	signed, err := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil{
+		t.Fatalf("sign none-alg token: %v", err)
+	}
+
+	router := newTestRouter(cfg, "")
+	rec := doRequest(router, signed)
+	if rec.Code != http.StatusUnauthorized{
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestMiddlewareRejectsEmptySecret(t *testing.T){
+	cfg := Config{Secret: []byte("real-secret")}
+	token, err := cfg.IssueToken("tester", nil, time.Hour)
+	if err != nil{
+		t.Fatalf("issue token: %v", err)
+	}
+
+	forged := Config{Secret: nil}
+	router := newTestRouter(forged, "")
+	rec := doRequest(router, token)
+	if rec.Code != http.StatusUnauthorized{
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestMiddlewareAcceptsValidToken(t *testing.T){
+	cfg := Config{Secret: []byte("test-secret")}
+	token, err := cfg.IssueToken("tester", []string{"librarian"}, time.Hour)
+	if err != nil{
+		t.Fatalf("issue token: %v", err)
+	}
+
+	router := newTestRouter(cfg, "")
+	rec := doRequest(router, token)
+	if rec.Code != http.StatusOK{
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestRequireRoleRejectsMissingRole(t *testing.T){
+	cfg := Config{Secret: []byte("test-secret")}
+	token, err := cfg.IssueToken("tester", []string{"reader"}, time.Hour)
+	if err != nil{
+		t.Fatalf("issue token: %v", err)
+	}
+
+	router := newTestRouter(cfg, "librarian")
+	rec := doRequest(router, token)
+	if rec.Code != http.StatusForbidden{
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
+
+func TestRequireRoleAcceptsMatchingRole(t *testing.T){
+	cfg := Config{Secret: []byte("test-secret")}
+	token, err := cfg.IssueToken("tester", []string{"reader", "librarian"}, time.Hour)
+	if err != nil{
+		t.Fatalf("issue token: %v", err)
+	}
+
+	router := newTestRouter(cfg, "librarian")
+	rec := doRequest(router, token)
+	if rec.Code != http.StatusOK{
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
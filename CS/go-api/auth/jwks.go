@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+//jwksCache fetches and caches RS256 public keys from a JWKS endpoint, keyed
+//by "kid", refreshing at most once a minute when a kid isn't found.
+type jwksCache struct{
+	url string
+
+	mu		sync.Mutex
+	keys	map[string]*rsa.PublicKey
+	fetched time.Time
+}
+
+func newJWKSCache(url string) *jwksCache{
+	return &jwksCache{url: url, keys: map[string]*rsa.PublicKey{}}
+}
+
+type jwksResponse struct{
+	Keys []struct{
+		Kid string `json:"kid"`
+		N	string `json:"n"`
+		E	string `json:"e"`
+	} `json:"keys"`
+}
+
+func (c *jwksCache) keyFor(token *jwt.Token) (interface{}, error){
+	kid, _ := token.Header["kid"].(string)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if key, ok := c.keys[kid]; ok{
+		return key, nil
+	}
+	if time.Since(c.fetched) < time.Minute{
+		return nil, fmt.Errorf("auth: unknown kid %q", kid)
+	}
+
+	c.fetched = time.Now()
+	if err := c.refresh(); err != nil{
+		return nil, err
+	}
+
+	key, ok := c.keys[kid]
+	if !ok{
+		return nil, fmt.Errorf("auth: unknown kid %q", kid)
+	}
+	return key, nil
+}
+
+func (c *jwksCache) refresh() error{
+	resp, err := http.Get(c.url)
+	if err != nil{
+		return err
+	}
+	defer resp.Body.Close()
+
+	var body jwksResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil{
+		return err
+	}
+
+	for _, k := range body.Keys{
+		n, err := decodeBigInt(k.N)
+		if err != nil{
+			continue
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil{
+			continue
+		}
+		c.keys[k.Kid] = &rsa.PublicKey{N: n, E: int(e.Int64())}
+	}
+	return nil
+}
+
+func decodeBigInt(s string) (*big.Int, error){
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil{
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
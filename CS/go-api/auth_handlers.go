@@ -0,0 +1,91 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/ryanmogauro/Tutor.AI/CS/go-api/auth"
+)
+
+//tokenTTL is how long an issued access token stays valid.
+const tokenTTL = 24 * time.Hour
+
+//authHandler wires /auth/register and /auth/login against the UserStore
+//and the auth package's token issuer.
+type authHandler struct{
+	users UserStore
+	auth  auth.Config
+}
+
+func newAuthHandler(users UserStore, cfg auth.Config) *authHandler{
+	return &authHandler{users: users, auth: cfg}
+}
+
+type registerRequest struct{
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+func (h *authHandler) register(c *gin.Context){
+	var req registerRequest
+	if err := c.BindJSON(&req); err != nil{
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil{
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"message": "Could Not Register User"})
+		return
+	}
+
+	user, err := h.users.CreateUser(User{
+		ID:			  req.Username,
+		Username:	  req.Username,
+		PasswordHash: string(hash),
+		Roles:		  []string{"reader"},
+	})
+	if err == ErrUserExists{
+		c.IndentedJSON(http.StatusConflict, gin.H{"message": "User Already Exists"})
+		return
+	}
+	if err != nil{
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"message": "Could Not Register User"})
+		return
+	}
+
+	c.IndentedJSON(http.StatusCreated, gin.H{"id": user.ID, "username": user.Username})
+}
+
+type loginRequest struct{
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+func (h *authHandler) login(c *gin.Context){
+	var req loginRequest
+	if err := c.BindJSON(&req); err != nil{
+		return
+	}
+
+	user, err := h.users.GetByUsername(req.Username)
+	if err != nil{
+		c.IndentedJSON(http.StatusUnauthorized, gin.H{"message": "Invalid Credentials"})
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil{
+		c.IndentedJSON(http.StatusUnauthorized, gin.H{"message": "Invalid Credentials"})
+		return
+	}
+
+	token, err := h.auth.IssueToken(user.ID, user.Roles, tokenTTL)
+	if err != nil{
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"message": "Could Not Issue Token"})
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"token": token})
+}
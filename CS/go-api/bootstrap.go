@@ -0,0 +1,40 @@
+package main
+
+import (
+	"log"
+	"os"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+//bootstrapLibrarian provisions the first "librarian" account from
+//BOOTSTRAP_LIBRARIAN_USERNAME/BOOTSTRAP_LIBRARIAN_PASSWORD, if set and the
+//account doesn't already exist. Without this, RequireRole("librarian")
+//would gate every write endpoint behind a role nothing could ever grant,
+//since register always hands out "reader".
+func bootstrapLibrarian(users UserStore){
+	username := os.Getenv("BOOTSTRAP_LIBRARIAN_USERNAME")
+	password := os.Getenv("BOOTSTRAP_LIBRARIAN_PASSWORD")
+	if username == "" || password == ""{
+		return
+	}
+
+	if _, err := users.GetByUsername(username); err == nil{
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil{
+		log.Fatalf("bootstrap librarian: %v", err)
+	}
+
+	_, err = users.CreateUser(User{
+		ID:			  username,
+		Username:	  username,
+		PasswordHash: string(hash),
+		Roles:		  []string{"librarian"},
+	})
+	if err != nil{
+		log.Fatalf("bootstrap librarian: %v", err)
+	}
+}
@@ -2,11 +2,22 @@
 package main
 
 import(
+	"log"
 	"net/http"
-	"errors"
+	"os"
+
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+	"github.com/swaggo/swag"
+
+	"github.com/ryanmogauro/Tutor.AI/CS/go-api/auth"
+	_ "github.com/ryanmogauro/Tutor.AI/CS/go-api/docs"
 )
 
+//go:generate swag init --parseDependency --parseInternal
+
 //Capital field names for outside access (?)
 type book struct{
 	ID 		string	`json:"id"`
@@ -15,31 +26,89 @@ type book struct{
 	Quantity int	`json:"quantity"`
 }
 
-var books = []book{
-	{ID: "1", Title: "Shoe Dog", Author: "Phil Knight", Quantity: 2}, 
-	{ID: "2", Title: "Zero to One", Author: "Peter Thiel", Quantity: 1}, 
-	{ID: "3", Title: "Crime and Punishment", Author: "Fyodor D", Quantity: 2}, 
+//bookHandler holds the BookStore handlers are wired against, so routes no
+//longer reach into package-level state.
+type bookHandler struct{
+	store BookStore
+}
+
+func newBookHandler(store BookStore) *bookHandler{
+	return &bookHandler{store: store}
 }
 
-func getBooks(c *gin.Context){
+//getBooks godoc
+//@Summary		List books
+//@Description	Returns a page of books, optionally filtered and sorted
+//@Tags			books
+//@Produce		json
+//@Param			page		query	int		false	"page number"			default(1)
+//@Param			page_size	query	int		false	"results per page"		default(20)
+//@Param			author		query	string	false	"filter by author (substring, case-insensitive)"
+//@Param			title		query	string	false	"filter by title (substring, case-insensitive)"
+//@Param			in_stock	query	bool	false	"filter to books with quantity > 0"
+//@Param			sort		query	string	false	"sort field, e.g. title or -quantity"
+//@Success		200	{object}	bookListResponse
+//@Failure		400	{object}	gin.H
+//@Router			/books [get]
+func (h *bookHandler) getBooks(c *gin.Context){
+	q, err := parseBookQuery(c)
+	if err != nil{
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	books, total, err := h.store.List(q)
+	if err != nil{
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"message":"Could Not List Books"})
+		return
+	}
+
 	//status, data
 	//auto formats json body
-	c.IndentedJSON(http.StatusOK, books)
+	c.IndentedJSON(http.StatusOK, newBookListResponse(c, books, q, total))
 }
 
-func createBook(c *gin.Context){
+//createBook godoc
+//@Summary		Create a book
+//@Tags			books
+//@Accept			json
+//@Produce		json
+//@Security		BearerAuth
+//@Param			book	body		book	true	"book to create"
+//@Success		200		{object}	book
+//@Failure		409		{object}	gin.H
+//@Failure		500		{object}	gin.H
+//@Router			/books [post]
+func (h *bookHandler) createBook(c *gin.Context){
 	var newBook book
 
 	if err := c.BindJSON(&newBook); err != nil{
 		return
 	}
-	books = append(books, newBook)
-	c.IndentedJSON(http.StatusOK, newBook)
+
+	created, err := h.store.Create(newBook)
+	if err == ErrBookExists{
+		c.IndentedJSON(http.StatusConflict, gin.H{"message":"Book Already Exists"})
+		return
+	}
+	if err != nil{
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"message":"Could Not Create Book"})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, created)
 }
 
-func bookByID(c *gin.Context){
+//bookByID godoc
+//@Summary		Get a book by id
+//@Tags			books
+//@Produce		json
+//@Param			id	path		string	true	"book id"
+//@Success		200	{object}	book
+//@Failure		404	{object}	gin.H
+//@Router			/books/{id} [get]
+func (h *bookHandler) bookByID(c *gin.Context){
 	id := c.Param("id")
-	book, err := getBookByID(id)
+	book, err := h.store.Get(id)
 
 	if err != nil{
 		c.IndentedJSON(http.StatusNotFound, gin.H{"message":"Book Not Found"})
@@ -49,27 +118,170 @@ func bookByID(c *gin.Context){
 	c.IndentedJSON(http.StatusOK, book)
 }
 
-//takes in target id string
-//returns pointer to book if it exists
-func getBookByID(id string) (*book, error){
+//updateBook godoc
+//@Summary		Update a book
+//@Description	Partial update of title/author/quantity
+//@Tags			books
+//@Accept			json
+//@Produce		json
+//@Security		BearerAuth
+//@Param			id		path		string		true	"book id"
+//@Param			updates	body		BookUpdate	true	"fields to update"
+//@Success		200		{object}	book
+//@Failure		404		{object}	gin.H
+//@Router			/books/{id} [patch]
+func (h *bookHandler) updateBook(c *gin.Context){
+	id := c.Param("id")
+
+	var updates BookUpdate
+	if err := c.BindJSON(&updates); err != nil{
+		return
+	}
+
+	updated, err := h.store.Update(id, updates)
+	if err != nil{
+		c.IndentedJSON(http.StatusNotFound, gin.H{"message":"Book Not Found"})
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, updated)
+}
+
+//checkoutBook godoc
+//@Summary		Check out a book
+//@Description	Decrements quantity by one, 4xx when unavailable
+//@Tags			books
+//@Produce		json
+//@Security		BearerAuth
+//@Param			id	path		string	true	"book id"
+//@Success		200	{object}	book
+//@Failure		400	{object}	gin.H
+//@Failure		404	{object}	gin.H
+//@Router			/books/{id}/checkout [put]
+func (h *bookHandler) checkoutBook(c *gin.Context){
+	id := c.Param("id")
+
+	updated, err := h.store.AdjustQuantity(id, -1)
+	if err == ErrBookNotFound{
+		c.IndentedJSON(http.StatusNotFound, gin.H{"message":"Book Not Found"})
+		return
+	}
+	if err == ErrBookUnavailable{
+		c.IndentedJSON(http.StatusBadRequest, gin.H{"message":"Book Not Available"})
+		return
+	}
+	if err != nil{
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"message":"Could Not Checkout Book"})
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, updated)
+}
+
+//returnBook godoc
+//@Summary		Return a book
+//@Description	Increments quantity by one
+//@Tags			books
+//@Produce		json
+//@Security		BearerAuth
+//@Param			id	path		string	true	"book id"
+//@Success		200	{object}	book
+//@Failure		404	{object}	gin.H
+//@Router			/books/{id}/return [put]
+func (h *bookHandler) returnBook(c *gin.Context){
+	id := c.Param("id")
 
-	for i, b := range books{
-		if b.ID == id{
-			//returns pointer for modification by other methods
-			return &books[i], nil
-		}
+	updated, err := h.store.AdjustQuantity(id, 1)
+	if err == ErrBookNotFound{
+		c.IndentedJSON(http.StatusNotFound, gin.H{"message":"Book Not Found"})
+		return
+	}
+	if err != nil{
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"message":"Could Not Return Book"})
+		return
 	}
-	return nil, errors.New("Book Not Found")
 
+	c.IndentedJSON(http.StatusOK, updated)
 }
 
+//deleteBook godoc
+//@Summary		Delete a book
+//@Tags			books
+//@Produce		json
+//@Security		BearerAuth
+//@Param			id	path		string	true	"book id"
+//@Success		200	{object}	gin.H
+//@Failure		404	{object}	gin.H
+//@Router			/books/{id} [delete]
+func (h *bookHandler) deleteBook(c *gin.Context){
+	id := c.Param("id")
+
+	if err := h.store.Delete(id); err != nil{
+		c.IndentedJSON(http.StatusNotFound, gin.H{"message":"Book Not Found"})
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message":"Book Deleted"})
+}
+
+//openAPISpec godoc
+//@Summary		Raw OpenAPI spec
+//@Description	Serves the generated OpenAPI spec as JSON, for codegen tools
+//@Tags			docs
+//@Produce		json
+//@Success		200	{object}	object
+//@Router			/openapi.json [get]
+func openAPISpec(c *gin.Context){
+	spec, err := swag.ReadDoc()
+	if err != nil{
+		c.IndentedJSON(http.StatusInternalServerError, gin.H{"message":"Could Not Load OpenAPI Spec"})
+		return
+	}
+	c.Data(http.StatusOK, "application/json", []byte(spec))
+}
+
+//@title			Book Store API
+//@version		1.0
+//@description	CRUD API for a small book store.
+//@BasePath		/
+//@securityDefinitions.apikey	BearerAuth
+//@in								header
+//@name							Authorization
 //main func
 func main(){
-	router := gin.Default()
-	router.GET("/books", getBooks)
-	router.Run("localhost:8080")
-}
+	store, err := newStore(os.Getenv("STORAGE_BACKEND"), os.Getenv("STORAGE_DSN"))
+	if err != nil{
+		log.Fatal(err)
+	}
+	h := newBookHandler(store)
+
+	authCfg, err := auth.NewConfigFromEnv()
+	if err != nil{
+		log.Fatal(err)
+	}
+	authH := newAuthHandler(store, authCfg)
+	bootstrapLibrarian(store)
 
+	router := gin.New()
+	router.Use(gin.Recovery(), requestID(), requestLogger(), metrics())
 
+	router.POST("/auth/register", authH.register)
+	router.POST("/auth/login", authH.login)
 
+	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	router.GET("/openapi.json", openAPISpec)
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
+	router.GET("/books", h.getBooks)
+	router.GET("/books/:id", h.bookByID)
+
+	writes := router.Group("/books")
+	writes.Use(authCfg.Middleware(), auth.RequireRole("librarian"))
+	writes.POST("", h.createBook)
+	writes.PATCH("/:id", h.updateBook)
+	writes.PUT("/:id/checkout", h.checkoutBook)
+	writes.PUT("/:id/return", h.returnBook)
+	writes.DELETE("/:id", h.deleteBook)
+
+	router.Run("localhost:8080")
+}
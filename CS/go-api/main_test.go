@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ryanmogauro/Tutor.AI/CS/go-api/auth"
+)
+
+//TestConcurrentCreateAndGet hammers POST /books and GET /books/:id from many
+//goroutines at once. Run with -race: a memoryStore without its RWMutex
+//corrupts the books map under concurrent access.
+func TestConcurrentCreateAndGet(t *testing.T){
+	gin.SetMode(gin.TestMode)
+
+	store := newMemoryStore()
+	h := newBookHandler(store)
+
+	authCfg := auth.Config{Secret: []byte("test-secret")}
+	token, err := authCfg.IssueToken("tester", []string{"librarian"}, time.Hour)
+	if err != nil{
+		t.Fatalf("issue token: %v", err)
+	}
+
+	router := gin.New()
+	router.GET("/books/:id", h.bookByID)
+
+	writes := router.Group("/books")
+	writes.Use(authCfg.Middleware(), auth.RequireRole("librarian"))
+	writes.POST("", h.createBook)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++{
+		wg.Add(2)
+
+		go func(i int){
+			defer wg.Done()
+
+			body, _ := json.Marshal(book{ID: string(rune('a' + i%26)), Title: "t", Author: "a", Quantity: 1})
+			req := httptest.NewRequest(http.MethodPost, "/books", bytes.NewReader(body))
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+		}(i)
+
+		go func(){
+			defer wg.Done()
+
+			req := httptest.NewRequest(http.MethodGet, "/books/1", nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize		= 100
+)
+
+//bookListResponse is the envelope GET /books returns: the current page of
+//data alongside paging metadata, mirrored in the Link header as RFC 5988
+//"next"/"prev" relations.
+type bookListResponse struct{
+	Data	 []book `json:"data"`
+	Page	 int	`json:"page"`
+	PageSize int	`json:"page_size"`
+	Total	 int	`json:"total"`
+	Next	 string `json:"next,omitempty"`
+	Prev	 string `json:"prev,omitempty"`
+}
+
+//parseBookQuery reads page/page_size/author/title/in_stock/sort from the
+//request's query params into a BookQuery, applying defaults and clamping
+//page_size to maxPageSize.
+func parseBookQuery(c *gin.Context) (BookQuery, error){
+	q := BookQuery{Page: 1, PageSize: defaultPageSize}
+
+	if v := c.Query("page"); v != ""{
+		page, err := strconv.Atoi(v)
+		if err != nil || page < 1{
+			return BookQuery{}, errors.New("page must be a positive integer")
+		}
+		q.Page = page
+	}
+
+	if v := c.Query("page_size"); v != ""{
+		size, err := strconv.Atoi(v)
+		if err != nil || size < 1{
+			return BookQuery{}, errors.New("page_size must be a positive integer")
+		}
+		if size > maxPageSize{
+			size = maxPageSize
+		}
+		q.PageSize = size
+	}
+
+	q.Author = c.Query("author")
+	q.Title = c.Query("title")
+
+	if v := c.Query("in_stock"); v != ""{
+		inStock, err := strconv.ParseBool(v)
+		if err != nil{
+			return BookQuery{}, errors.New("in_stock must be true or false")
+		}
+		q.InStock = &inStock
+	}
+
+	if v := c.Query("sort"); v != ""{
+		field := strings.TrimPrefix(v, "-")
+		q.SortDesc = strings.HasPrefix(v, "-")
+		q.SortField = field
+	}
+
+	return q, nil
+}
+
+//newBookListResponse builds the envelope for a page of results, including
+//next/prev URLs (and, via setLinkHeader, the matching Link header).
+func newBookListResponse(c *gin.Context, books []book, q BookQuery, total int) bookListResponse{
+	resp := bookListResponse{Data: books, Page: q.Page, PageSize: q.PageSize, Total: total}
+
+	if q.Page*q.PageSize < total{
+		resp.Next = pageURL(c, q.Page+1)
+	}
+	if q.Page > 1{
+		resp.Prev = pageURL(c, q.Page-1)
+	}
+
+	setLinkHeader(c, resp.Next, resp.Prev)
+	return resp
+}
+
+//pageURL rebuilds the current request URL with its "page" query param
+//replaced, for use in the response envelope and Link header.
+func pageURL(c *gin.Context, page int) string{
+	values := c.Request.URL.Query()
+	values.Set("page", strconv.Itoa(page))
+
+	u := *c.Request.URL
+	u.RawQuery = values.Encode()
+	return u.String()
+}
+
+func setLinkHeader(c *gin.Context, next, prev string){
+	var links []string
+	if next != ""{
+		links = append(links, `<`+next+`>; rel="next"`)
+	}
+	if prev != ""{
+		links = append(links, `<`+prev+`>; rel="prev"`)
+	}
+	if len(links) > 0{
+		c.Header("Link", strings.Join(links, ", "))
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import "errors"
+
+//ErrBookNotFound is returned by a BookStore when no book matches the given id
+var ErrBookNotFound = errors.New("book not found")
+
+//ErrBookUnavailable is returned by AdjustQuantity when the adjustment would
+//take a book's quantity below zero
+var ErrBookUnavailable = errors.New("book not available")
+
+//ErrBookExists is returned by Create when a book with the given id already
+//exists, so all backends reject a duplicate id the same way instead of the
+//memory store silently overwriting it while SQL backends surface a raw
+//driver error.
+var ErrBookExists = errors.New("book already exists")
+
+//ErrUserNotFound is returned by a UserStore when no account matches
+var ErrUserNotFound = errors.New("user not found")
+
+//ErrUserExists is returned by CreateUser when the username is already taken
+var ErrUserExists = errors.New("user already exists")
+
+//BookUpdate carries the fields a PATCH may change; nil means "leave as is"
+type BookUpdate struct{
+	Title 	 *string `json:"title"`
+	Author	 *string `json:"author"`
+	Quantity *int	 `json:"quantity"`
+}
+
+//BookQuery narrows and orders a List call. PageSize and Page are always
+//positive (the handler fills in defaults); Author and Title are
+//case-insensitive substring filters, InStock filters on Quantity > 0 when
+//set, and SortField/SortDesc order the results ("title" or "quantity",
+//ascending unless SortDesc is set).
+type BookQuery struct{
+	Page	  int
+	PageSize  int
+	Author	  string
+	Title	  string
+	InStock	  *bool
+	SortField string
+	SortDesc  bool
+}
+
+//BookStore is the persistence boundary for books. Handlers talk to a
+//BookStore instead of touching package-level state so the backend can be
+//swapped out (in-memory, SQLite, Postgres) without changing handler code.
+//List pushes filtering/sorting/pagination down to SQL backends and returns
+//the total number of matching rows alongside the current page.
+type BookStore interface{
+	List(q BookQuery) (books []book, total int, err error)
+	Get(id string) (book, error)
+	Create(b book) (book, error)
+	Update(id string, updates BookUpdate) (book, error)
+	Delete(id string) error
+	AdjustQuantity(id string, delta int) (book, error)
+}
+
+//User is an account that can authenticate; Roles gates write access to the
+//books API (see auth.RequireRole).
+type User struct{
+	ID			 string
+	Username	 string
+	PasswordHash string
+	Roles		 []string
+}
+
+//UserStore is the persistence boundary for accounts backing JWT auth.
+type UserStore interface{
+	GetByUsername(username string) (User, error)
+	CreateUser(u User) (User, error)
+}
+
+//Store is the full persistence surface the API needs: books plus the user
+//accounts that back authentication. Each backend implements both halves
+//against the same underlying connection.
+type Store interface{
+	BookStore
+	UserStore
+}
+
+//newStore builds the Store selected by the STORAGE_BACKEND env var.
+//Supported values are "memory" (default), "sqlite", and "postgres"; sqlite
+//and postgres read their DSN from STORAGE_DSN.
+func newStore(backend, dsn string) (Store, error){
+	switch backend{
+	case "", "memory":
+		return newMemoryStore(), nil
+	case "sqlite":
+		return newSQLiteStore(dsn)
+	case "postgres":
+		return newPostgresStore(dsn)
+	default:
+		return nil, errors.New("unknown STORAGE_BACKEND: " + backend)
+	}
+}
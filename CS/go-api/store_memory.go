@@ -0,0 +1,178 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+//memoryStore is the default BookStore backend: maps keyed by id, guarded by
+//a single RWMutex. Reads take RLock, writes take Lock, and every method
+//returns a copy of book/User rather than a pointer into the map so callers
+//can't mutate shared state outside the lock.
+type memoryStore struct{
+	mu	  sync.RWMutex
+	books map[string]book
+	users map[string]User
+}
+
+func newMemoryStore() *memoryStore{
+	store := &memoryStore{books: map[string]book{}, users: map[string]User{}}
+	for _, b := range []book{
+		{ID: "1", Title: "Shoe Dog", Author: "Phil Knight", Quantity: 2},
+		{ID: "2", Title: "Zero to One", Author: "Peter Thiel", Quantity: 1},
+		{ID: "3", Title: "Crime and Punishment", Author: "Fyodor D", Quantity: 2},
+	}{
+		store.books[b.ID] = b
+	}
+	return store
+}
+
+func (s *memoryStore) List(q BookQuery) ([]book, int, error){
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	matches := make([]book, 0, len(s.books))
+	for _, b := range s.books{
+		if q.Author != "" && !strings.Contains(strings.ToLower(b.Author), strings.ToLower(q.Author)){
+			continue
+		}
+		if q.Title != "" && !strings.Contains(strings.ToLower(b.Title), strings.ToLower(q.Title)){
+			continue
+		}
+		if q.InStock != nil && (b.Quantity > 0) != *q.InStock{
+			continue
+		}
+		matches = append(matches, b)
+	}
+
+	sortBooks(matches, q.SortField, q.SortDesc)
+
+	total := len(matches)
+	start := (q.Page - 1) * q.PageSize
+	if start > total{
+		start = total
+	}
+	end := start + q.PageSize
+	if end > total{
+		end = total
+	}
+
+	return matches[start:end], total, nil
+}
+
+func sortBooks(books []book, field string, desc bool){
+	less := func(i, j int) bool{
+		switch field{
+		case "quantity":
+			return books[i].Quantity < books[j].Quantity
+		case "author":
+			return books[i].Author < books[j].Author
+		default:
+			return books[i].Title < books[j].Title
+		}
+	}
+	if desc{
+		inner := less
+		less = func(i, j int) bool{ return inner(j, i) }
+	}
+	sort.SliceStable(books, less)
+}
+
+func (s *memoryStore) Get(id string) (book, error){
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	b, ok := s.books[id]
+	if !ok{
+		return book{}, ErrBookNotFound
+	}
+	return b, nil
+}
+
+func (s *memoryStore) Create(b book) (book, error){
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.books[b.ID]; ok{
+		return book{}, ErrBookExists
+	}
+
+	s.books[b.ID] = b
+	return b, nil
+}
+
+func (s *memoryStore) Update(id string, updates BookUpdate) (book, error){
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.books[id]
+	if !ok{
+		return book{}, ErrBookNotFound
+	}
+
+	if updates.Title != nil{
+		b.Title = *updates.Title
+	}
+	if updates.Author != nil{
+		b.Author = *updates.Author
+	}
+	if updates.Quantity != nil{
+		b.Quantity = *updates.Quantity
+	}
+
+	s.books[id] = b
+	return b, nil
+}
+
+func (s *memoryStore) Delete(id string) error{
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.books[id]; !ok{
+		return ErrBookNotFound
+	}
+	delete(s.books, id)
+	return nil
+}
+
+func (s *memoryStore) AdjustQuantity(id string, delta int) (book, error){
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.books[id]
+	if !ok{
+		return book{}, ErrBookNotFound
+	}
+	if b.Quantity+delta < 0{
+		return book{}, ErrBookUnavailable
+	}
+	b.Quantity += delta
+	s.books[id] = b
+	return b, nil
+}
+
+func (s *memoryStore) GetByUsername(username string) (User, error){
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, u := range s.users{
+		if u.Username == username{
+			return u, nil
+		}
+	}
+	return User{}, ErrUserNotFound
+}
+
+func (s *memoryStore) CreateUser(u User) (User, error){
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.users{
+		if existing.Username == u.Username{
+			return User{}, ErrUserExists
+		}
+	}
+	s.users[u.ID] = u
+	return u, nil
+}
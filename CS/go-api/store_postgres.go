@@ -0,0 +1,236 @@
+package main
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//isPostgresUniqueViolation reports whether err is a Postgres unique_violation (SQLSTATE
+//23505), as opposed to a transient connection/driver error that happens to
+//surface from the same Create call.
+func isPostgresUniqueViolation(err error) bool{
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr){
+		return pgErr.Code == "23505"
+	}
+	return false
+}
+
+//bookModel is the GORM row type for the postgres backend. It mirrors book
+//but keeps the gorm tags out of the API-facing struct.
+type bookModel struct{
+	ID 		string `gorm:"primaryKey"`
+	Title 	string
+	Author	string
+	Quantity int
+}
+
+func (bookModel) TableName() string{ return "books" }
+
+//userModel is the GORM row type for accounts; roles are stored comma-joined
+//since they're small, fixed, and never queried on individually.
+type userModel struct{
+	ID			 string `gorm:"primaryKey"`
+	Username	 string `gorm:"uniqueIndex"`
+	PasswordHash string
+	Roles		 string
+}
+
+func (userModel) TableName() string{ return "users" }
+
+//postgresStore is a BookStore backed by Postgres via GORM, selected with
+//STORAGE_BACKEND=postgres and a STORAGE_DSN connection string.
+type postgresStore struct{
+	db *gorm.DB
+}
+
+func newPostgresStore(dsn string) (*postgresStore, error){
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil{
+		return nil, err
+	}
+
+	if err := db.AutoMigrate(&bookModel{}, &userModel{}); err != nil{
+		return nil, err
+	}
+
+	return &postgresStore{db: db}, nil
+}
+
+func toBook(m bookModel) book{
+	return book{ID: m.ID, Title: m.Title, Author: m.Author, Quantity: m.Quantity}
+}
+
+func (s *postgresStore) List(q BookQuery) ([]book, int, error){
+	scope := s.db.Model(&bookModel{})
+	if q.Author != ""{
+		scope = scope.Where("LOWER(author) LIKE ?", "%"+strings.ToLower(q.Author)+"%")
+	}
+	if q.Title != ""{
+		scope = scope.Where("LOWER(title) LIKE ?", "%"+strings.ToLower(q.Title)+"%")
+	}
+	if q.InStock != nil{
+		if *q.InStock{
+			scope = scope.Where("quantity > 0")
+		} else{
+			scope = scope.Where("quantity <= 0")
+		}
+	}
+
+	var total int64
+	if err := scope.Count(&total).Error; err != nil{
+		return nil, 0, err
+	}
+
+	column := "title"
+	switch q.SortField{
+	case "quantity":
+		column = "quantity"
+	case "author":
+		column = "author"
+	}
+	order := column + " ASC"
+	if q.SortDesc{
+		order = column + " DESC"
+	}
+
+	var models []bookModel
+	err := scope.Order(order).Limit(q.PageSize).Offset((q.Page - 1) * q.PageSize).Find(&models).Error
+	if err != nil{
+		return nil, 0, err
+	}
+
+	books := make([]book, 0, len(models))
+	for _, m := range models{
+		books = append(books, toBook(m))
+	}
+	return books, int(total), nil
+}
+
+func (s *postgresStore) Get(id string) (book, error){
+	var m bookModel
+	err := s.db.First(&m, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound){
+		return book{}, ErrBookNotFound
+	}
+	if err != nil{
+		return book{}, err
+	}
+	return toBook(m), nil
+}
+
+func (s *postgresStore) Create(b book) (book, error){
+	m := bookModel{ID: b.ID, Title: b.Title, Author: b.Author, Quantity: b.Quantity}
+	if err := s.db.Create(&m).Error; err != nil{
+		if isPostgresUniqueViolation(err){
+			return book{}, ErrBookExists
+		}
+		return book{}, err
+	}
+	return toBook(m), nil
+}
+
+//Update runs the read-modify-write inside a transaction with
+//SELECT ... FOR UPDATE, matching AdjustQuantity, so two concurrent PATCHes
+//can't race and silently drop one writer's change.
+func (s *postgresStore) Update(id string, updates BookUpdate) (book, error){
+	var result book
+	err := s.db.Transaction(func(tx *gorm.DB) error{
+		var m bookModel
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&m, "id = ?", id).Error; err != nil{
+			if errors.Is(err, gorm.ErrRecordNotFound){
+				return ErrBookNotFound
+			}
+			return err
+		}
+
+		if updates.Title != nil{
+			m.Title = *updates.Title
+		}
+		if updates.Author != nil{
+			m.Author = *updates.Author
+		}
+		if updates.Quantity != nil{
+			m.Quantity = *updates.Quantity
+		}
+
+		if err := tx.Save(&m).Error; err != nil{
+			return err
+		}
+		result = toBook(m)
+		return nil
+	})
+	if err != nil{
+		return book{}, err
+	}
+	return result, nil
+}
+
+func (s *postgresStore) Delete(id string) error{
+	res := s.db.Delete(&bookModel{}, "id = ?", id)
+	if res.Error != nil{
+		return res.Error
+	}
+	if res.RowsAffected == 0{
+		return ErrBookNotFound
+	}
+	return nil
+}
+
+//AdjustQuantity runs the read-check-write inside a transaction with
+//SELECT ... FOR UPDATE so two concurrent checkouts can't both read the
+//same quantity and both pass the availability check.
+func (s *postgresStore) AdjustQuantity(id string, delta int) (book, error){
+	var result book
+	err := s.db.Transaction(func(tx *gorm.DB) error{
+		var m bookModel
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&m, "id = ?", id).Error; err != nil{
+			if errors.Is(err, gorm.ErrRecordNotFound){
+				return ErrBookNotFound
+			}
+			return err
+		}
+		if m.Quantity+delta < 0{
+			return ErrBookUnavailable
+		}
+
+		m.Quantity += delta
+		if err := tx.Save(&m).Error; err != nil{
+			return err
+		}
+		result = toBook(m)
+		return nil
+	})
+	if err != nil{
+		return book{}, err
+	}
+	return result, nil
+}
+
+func (s *postgresStore) GetByUsername(username string) (User, error){
+	var m userModel
+	err := s.db.First(&m, "username = ?", username).Error
+	if errors.Is(err, gorm.ErrRecordNotFound){
+		return User{}, ErrUserNotFound
+	}
+	if err != nil{
+		return User{}, err
+	}
+	return User{ID: m.ID, Username: m.Username, PasswordHash: m.PasswordHash, Roles: strings.Split(m.Roles, ",")}, nil
+}
+
+func (s *postgresStore) CreateUser(u User) (User, error){
+	m := userModel{ID: u.ID, Username: u.Username, PasswordHash: u.PasswordHash, Roles: strings.Join(u.Roles, ",")}
+	if err := s.db.Create(&m).Error; err != nil{
+		if isPostgresUniqueViolation(err){
+			return User{}, ErrUserExists
+		}
+		return User{}, err
+	}
+	return u, nil
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+//isSQLiteUniqueViolation reports whether err is a SQLite UNIQUE constraint failure,
+//as opposed to a transient driver/connection error that happens to surface
+//from the same Exec call.
+func isSQLiteUniqueViolation(err error) bool{
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr){
+		return sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique ||
+			sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey
+	}
+	return false
+}
+
+//sqliteStore is a BookStore backed by a local SQLite file, selected with
+//STORAGE_BACKEND=sqlite and a STORAGE_DSN pointing at the db file.
+type sqliteStore struct{
+	db *sql.DB
+}
+
+func newSQLiteStore(dsn string) (*sqliteStore, error){
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil{
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS books (
+		id TEXT PRIMARY KEY,
+		title TEXT NOT NULL,
+		author TEXT NOT NULL,
+		quantity INTEGER NOT NULL
+	)`)
+	if err != nil{
+		return nil, err
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS users (
+		id TEXT PRIMARY KEY,
+		username TEXT NOT NULL UNIQUE,
+		password_hash TEXT NOT NULL,
+		roles TEXT NOT NULL
+	)`)
+	if err != nil{
+		return nil, err
+	}
+
+	return &sqliteStore{db: db}, nil
+}
+
+func (s *sqliteStore) List(q BookQuery) ([]book, int, error){
+	where, args := bookQueryWhere(q)
+
+	var total int
+	countRow := s.db.QueryRow(`SELECT COUNT(*) FROM books`+where, args...)
+	if err := countRow.Scan(&total); err != nil{
+		return nil, 0, err
+	}
+
+	listArgs := append(append([]interface{}{}, args...), q.PageSize, (q.Page-1)*q.PageSize)
+	rows, err := s.db.Query(
+		`SELECT id, title, author, quantity FROM books`+where+` ORDER BY `+bookQueryOrderBy(q)+` LIMIT ? OFFSET ?`,
+		listArgs...)
+	if err != nil{
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var books []book
+	for rows.Next(){
+		var b book
+		if err := rows.Scan(&b.ID, &b.Title, &b.Author, &b.Quantity); err != nil{
+			return nil, 0, err
+		}
+		books = append(books, b)
+	}
+	return books, total, rows.Err()
+}
+
+//bookQueryWhere builds a "WHERE ..." clause (or "" when unfiltered) and its
+//bind args for the author/title/in_stock filters on BookQuery.
+func bookQueryWhere(q BookQuery) (string, []interface{}){
+	var clauses []string
+	var args []interface{}
+
+	if q.Author != ""{
+		clauses = append(clauses, `LOWER(author) LIKE ?`)
+		args = append(args, "%"+strings.ToLower(q.Author)+"%")
+	}
+	if q.Title != ""{
+		clauses = append(clauses, `LOWER(title) LIKE ?`)
+		args = append(args, "%"+strings.ToLower(q.Title)+"%")
+	}
+	if q.InStock != nil{
+		if *q.InStock{
+			clauses = append(clauses, `quantity > 0`)
+		} else{
+			clauses = append(clauses, `quantity <= 0`)
+		}
+	}
+
+	if len(clauses) == 0{
+		return "", nil
+	}
+	return " WHERE " + strings.Join(clauses, " AND "), args
+}
+
+func bookQueryOrderBy(q BookQuery) string{
+	column := "title"
+	switch q.SortField{
+	case "quantity":
+		column = "quantity"
+	case "author":
+		column = "author"
+	}
+	if q.SortDesc{
+		return column + " DESC"
+	}
+	return column + " ASC"
+}
+
+func (s *sqliteStore) Get(id string) (book, error){
+	var b book
+	row := s.db.QueryRow(`SELECT id, title, author, quantity FROM books WHERE id = ?`, id)
+	if err := row.Scan(&b.ID, &b.Title, &b.Author, &b.Quantity); err != nil{
+		if err == sql.ErrNoRows{
+			return book{}, ErrBookNotFound
+		}
+		return book{}, err
+	}
+	return b, nil
+}
+
+func (s *sqliteStore) Create(b book) (book, error){
+	_, err := s.db.Exec(`INSERT INTO books (id, title, author, quantity) VALUES (?, ?, ?, ?)`,
+		b.ID, b.Title, b.Author, b.Quantity)
+	if isSQLiteUniqueViolation(err){
+		return book{}, ErrBookExists
+	}
+	if err != nil{
+		return book{}, err
+	}
+	return b, nil
+}
+
+//Update applies the given fields in a single UPDATE ... COALESCE statement
+//rather than reading the row, mutating it in Go, and writing it back, so two
+//concurrent PATCHes can't race and silently drop one writer's change.
+func (s *sqliteStore) Update(id string, updates BookUpdate) (book, error){
+	res, err := s.db.Exec(
+		`UPDATE books SET title = COALESCE(?, title), author = COALESCE(?, author), quantity = COALESCE(?, quantity) WHERE id = ?`,
+		nullableString(updates.Title), nullableString(updates.Author), nullableInt(updates.Quantity), id)
+	if err != nil{
+		return book{}, err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil{
+		return book{}, err
+	}
+	if rows == 0{
+		return book{}, ErrBookNotFound
+	}
+
+	return s.Get(id)
+}
+
+func nullableString(s *string) interface{}{
+	if s == nil{
+		return nil
+	}
+	return *s
+}
+
+func nullableInt(i *int) interface{}{
+	if i == nil{
+		return nil
+	}
+	return *i
+}
+
+func (s *sqliteStore) Delete(id string) error{
+	res, err := s.db.Exec(`DELETE FROM books WHERE id = ?`, id)
+	if err != nil{
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil{
+		return err
+	}
+	if rows == 0{
+		return ErrBookNotFound
+	}
+	return nil
+}
+
+//AdjustQuantity folds the read-check-write into a single UPDATE guarded by
+//its own WHERE clause, so two concurrent checkouts can't both read the same
+//quantity, both pass the availability check, and both write the same value.
+func (s *sqliteStore) AdjustQuantity(id string, delta int) (book, error){
+	res, err := s.db.Exec(
+		`UPDATE books SET quantity = quantity + ? WHERE id = ? AND quantity + ? >= 0`,
+		delta, id, delta)
+	if err != nil{
+		return book{}, err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil{
+		return book{}, err
+	}
+	if rows == 0{
+		if _, err := s.Get(id); err != nil{
+			return book{}, err
+		}
+		return book{}, ErrBookUnavailable
+	}
+
+	return s.Get(id)
+}
+
+func (s *sqliteStore) GetByUsername(username string) (User, error){
+	var u User
+	var roles string
+	row := s.db.QueryRow(`SELECT id, username, password_hash, roles FROM users WHERE username = ?`, username)
+	if err := row.Scan(&u.ID, &u.Username, &u.PasswordHash, &roles); err != nil{
+		if err == sql.ErrNoRows{
+			return User{}, ErrUserNotFound
+		}
+		return User{}, err
+	}
+	u.Roles = strings.Split(roles, ",")
+	return u, nil
+}
+
+func (s *sqliteStore) CreateUser(u User) (User, error){
+	_, err := s.db.Exec(`INSERT INTO users (id, username, password_hash, roles) VALUES (?, ?, ?, ?)`,
+		u.ID, u.Username, u.PasswordHash, strings.Join(u.Roles, ","))
+	if isSQLiteUniqueViolation(err){
+		return User{}, ErrUserExists
+	}
+	if err != nil{
+		return User{}, err
+	}
+	return u, nil
+}
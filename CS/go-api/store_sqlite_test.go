@@ -0,0 +1,52 @@
+package main
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+//TestSQLiteConcurrentCheckoutNeverOversells hammers AdjustQuantity from many
+//goroutines against a book with limited stock. Run with -race: a
+//read-then-write AdjustQuantity (no WHERE guard) lets two checkouts both
+//read the same quantity, both pass the availability check, and both write
+//the same decremented value, overselling stock that should have hit zero.
+func TestSQLiteConcurrentCheckoutNeverOversells(t *testing.T){
+	dsn := filepath.Join(t.TempDir(), "books.db")
+	store, err := newSQLiteStore(dsn)
+	if err != nil{
+		t.Fatalf("newSQLiteStore: %v", err)
+	}
+
+	const startingQuantity = 20
+	if _, err := store.Create(book{ID: "1", Title: "t", Author: "a", Quantity: startingQuantity}); err != nil{
+		t.Fatalf("create: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := 0
+	for i := 0; i < startingQuantity*2; i++{
+		wg.Add(1)
+		go func(){
+			defer wg.Done()
+			if _, err := store.AdjustQuantity("1", -1); err == nil{
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	got, err := store.Get("1")
+	if err != nil{
+		t.Fatalf("get: %v", err)
+	}
+	if got.Quantity < 0{
+		t.Fatalf("oversold: quantity = %d, want >= 0", got.Quantity)
+	}
+	if succeeded != startingQuantity-got.Quantity{
+		t.Fatalf("oversold: %d checkouts succeeded but quantity only fell from %d to %d", succeeded, startingQuantity, got.Quantity)
+	}
+}